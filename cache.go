@@ -2,7 +2,7 @@ package ttlcache
 
 import (
 	"runtime"
-	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -11,6 +11,13 @@ type Item struct {
 	Persistent bool
 	Object     interface{}
 	Expiration time.Time
+	// Accessed is the UnixNano time of the item's last Load (or of its
+	// creation, until it is first Load'ed), and Hits is
+	// the number of times it has been Load'ed. Both are maintained for
+	// the benefit of NewWithCapacity's LRU/LFU eviction policies; other
+	// callers can ignore them.
+	Accessed int64
+	Hits     int64
 }
 
 // Expired Returns true if the item has expired.
@@ -33,16 +40,41 @@ const (
 // Cache kv map with ttl
 type Cache struct {
 	defaultExpiration time.Duration
-	*sync.Map
+	store
 	stop chan bool
 	// If this is confusing, see the comment at the bottom of New()
+	onEvicted func(key string, value interface{})
+	// maxItems and policy are set by NewWithCapacity; maxItems <= 0 means
+	// unbounded, which is the default.
+	maxItems int
+	policy   EvictionPolicy
+	count    int64
 }
 
 // Store Cache Add an item to the cache, replacing any existing item. If the duration is 0
 // (DefaultExpiration), the cache's default expiration time is used. If it is -1
 // (NoExpiration), the item never expires.
 func (c *Cache) Store(k string, x interface{}, d time.Duration, persitent bool) {
-	// "Inlining" of set
+	item := c.newItem(x, d, persitent)
+	if c.maxItems <= 0 {
+		c.store.Store(k, item)
+		return
+	}
+	// Store always replaces, so use LoadOrStore (rather than a Load
+	// followed by a Store) to decide atomically whether k is new.
+	_, existed := c.store.LoadOrStore(k, item)
+	if existed {
+		c.store.Store(k, item)
+		return
+	}
+	if atomic.AddInt64(&c.count, 1) > int64(c.maxItems) {
+		c.evict()
+	}
+}
+
+// newItem builds an *Item for x, resolving DefaultExpiration against the
+// cache's configured default.
+func (c *Cache) newItem(x interface{}, d time.Duration, persistent bool) *Item {
 	var e time.Time
 	if d == DefaultExpiration {
 		d = c.defaultExpiration
@@ -50,52 +82,78 @@ func (c *Cache) Store(k string, x interface{}, d time.Duration, persitent bool)
 	if d > 0 {
 		e = time.Now().Add(d)
 	}
-
-	c.Map.Store(k, &Item{
-		Persistent: persitent,
+	return &Item{
+		Persistent: persistent,
 		Object:     x,
 		Expiration: e,
-	})
-	// TODO: Calls to mu.Unlock are currently not deferred because defer
-	// adds ~200 ns (as of go1.)
+		// Accessed starts out set to the insertion time so a just-stored
+		// item isn't mistaken for the least-recently-used one before it's
+		// ever been Load'ed.
+		Accessed: time.Now().UnixNano(),
+	}
 }
 
 // Load an item from the cache. Returns the item or nil, and a bool indicating
 // whether the key was found.
 func (c *Cache) Load(k string) (interface{}, bool) {
 	// "Inlining" of get and Expired
-	v, found := c.Map.Load(k)
+	item, found := c.store.Load(k)
 	if !found {
 		return nil, false
 	}
 
-	item := v.(*Item)
-
 	if !item.Persistent {
 		if time.Now().After(item.Expiration) {
-			c.Map.Delete(k)
+			// Route through Delete (rather than c.store.Delete directly)
+			// so lazily-reaped items still decrement the capacity counter
+			// and fire onEvicted, the same as an explicit Delete or the
+			// janitor's DeleteExpired sweep.
+			c.Delete(k)
 			return nil, false
 		}
 	}
+	atomic.StoreInt64(&item.Accessed, time.Now().UnixNano())
+	atomic.AddInt64(&item.Hits, 1)
 	return item.Object, true
 }
 
 // Delete an item from the cache. Does nothing if the key is not in the cache.
 func (c *Cache) Delete(k string) {
-	c.Map.Delete(k)
+	// Fast path: skip the extra work when there's no one listening and
+	// the cache isn't tracking how many items it holds.
+	if c.onEvicted == nil && c.maxItems <= 0 {
+		c.store.Delete(k)
+		return
+	}
+	// LoadAndDelete (rather than a separate Load+Delete) makes this atomic,
+	// so two goroutines racing to delete the same key (e.g. DeleteExpired's
+	// sweep racing a caller's Delete, or either racing Load's lazy-expiry
+	// path) can't both observe the item and double-fire onEvicted or
+	// double-decrement count.
+	item, found := c.store.LoadAndDelete(k)
+	if found {
+		if c.maxItems > 0 {
+			atomic.AddInt64(&c.count, -1)
+		}
+		if c.onEvicted != nil {
+			c.onEvicted(k, item.Object)
+		}
+	}
 }
 
-// Range calls f sequentially for each key and value present in the map. If f returns false, range stops the iteration.
+// Range calls f sequentially for each key and value (a *Item) present in the
+// cache. If f returns false, range stops the iteration.
 func (c *Cache) Range(f func(key, value interface{}) bool) {
-	c.Map.Range(f)
+	c.store.Range(func(k string, item *Item) bool {
+		return f(k, item)
+	})
 }
 
 // DeleteExpired iterate the map and deleted expired item
 func (c *Cache) DeleteExpired() {
-	c.Map.Range(func(k, v interface{}) bool {
-		item := v.(*Item)
+	c.store.Range(func(k string, item *Item) bool {
 		if item.Expired() {
-			c.Delete(k.(string))
+			c.Delete(k)
 		}
 		return true
 	})
@@ -118,33 +176,54 @@ func (c *Cache) gc(ci time.Duration) {
 	}
 }
 
-func newCache(de time.Duration, m *sync.Map) *Cache {
+func newCache(de time.Duration, s store) *Cache {
 	if de == 0 {
 		de = -1
 	}
 	c := &Cache{
 		defaultExpiration: de,
-		Map:               m,
+		store:             s,
 	}
 	return c
 }
 
+// startGC launches the cache's janitor goroutine (if the cache expires
+// items) and arranges for it to be stopped once c is no longer reachable.
+// This trick ensures that the janitor goroutine (which--granted it was
+// enabled--is running DeleteExpired on c forever) does not keep the
+// returned C object from being garbage collected. When it is garbage
+// collected, the finalizer stops the janitor goroutine, after which c can
+// be collected.
+func startGC(c *Cache, cleanupInterval time.Duration) {
+	if c.defaultExpiration > 0 {
+		go c.gc(cleanupInterval)
+		runtime.SetFinalizer(c, stopGC)
+	}
+}
+
 // New return a new cache with a given default expiration duration and cleanup
 // interval. If the expiration duration is less than one (or NoExpiration),
 // the items in the cache never expire (by default), and must be deleted
 // manually. If the cleanup interval is less than one, expired items are not
 // deleted from the cache before calling c.DeleteExpired().
 func New(defaultExpiration, cleanupInterval time.Duration) *Cache {
-	items := &sync.Map{}
-	c := newCache(defaultExpiration, items)
-	// This trick ensures that the janitor goroutine (which--granted it
-	// was enabled--is running DeleteExpired on c forever) does not keep
-	// the returned C object from being garbage collected. When it is
-	// garbage collected, the finalizer stops the janitor goroutine, after
-	// which c can be collected.
-	if defaultExpiration > 0 {
-		go c.gc(cleanupInterval)
-		runtime.SetFinalizer(c, stopGC)
+	// The janitor goroutine and its finalizer are set up by startGC, whose
+	// comment explains how they keep c collectable.
+	return NewFrom(defaultExpiration, cleanupInterval, nil)
+}
+
+// NewFrom is like New but initializes the cache's map with the given items.
+// This is useful when restoring a cache from a snapshot, e.g. the map
+// returned by another cache's Items, or one decoded with encoding/gob from
+// what Save wrote. (Restore and RestoreFile instead merge a saved snapshot
+// into an existing cache; they do not return the items.)
+func NewFrom(defaultExpiration, cleanupInterval time.Duration, items map[string]Item) *Cache {
+	s := &syncMapStore{}
+	for k, v := range items {
+		v := v
+		s.Store(k, &v)
 	}
+	c := newCache(defaultExpiration, s)
+	startGC(c, cleanupInterval)
 	return c
 }
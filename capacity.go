@@ -0,0 +1,100 @@
+package ttlcache
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// EvictionPolicy selects which item a capacity-bounded Cache (see
+// NewWithCapacity) evicts once Store would push it past its item limit.
+type EvictionPolicy int
+
+const (
+	// PolicyLRU evicts the least recently accessed non-persistent item,
+	// using Item.Accessed.
+	PolicyLRU EvictionPolicy = iota
+	// PolicyLFU evicts the least frequently accessed non-persistent item,
+	// using Item.Hits.
+	PolicyLFU
+	// PolicyRandom evicts the oldest (by Item.Accessed) of a small sample
+	// of non-persistent items, the "power of K choices" approximation:
+	// cheaper than scanning for an exact victim, and avoids maintaining a
+	// global heap.
+	PolicyRandom
+)
+
+// randomSampleSize is K in PolicyRandom's "power of K choices".
+const randomSampleSize = 5
+
+// NewWithCapacity returns a new cache like New, but bounded to maxItems
+// entries. Once the cache holds maxItems items, the next Store of a new key
+// evicts one existing, non-persistent item chosen by policy; persistent
+// items are never evicted, so a cache made up entirely of persistent items
+// is allowed to grow past maxItems.
+func NewWithCapacity(defaultExpiration, cleanupInterval time.Duration, maxItems int, policy EvictionPolicy) *Cache {
+	c := NewFrom(defaultExpiration, cleanupInterval, nil)
+	c.maxItems = maxItems
+	c.policy = policy
+	return c
+}
+
+// evict removes one non-persistent item, chosen by c.policy, after an insert
+// has pushed the cache over its capacity. The item just inserted is itself a
+// candidate: under PolicyLFU it starts with zero Hits, so it is the one
+// removed whenever every other non-persistent item has been Load'ed.
+func (c *Cache) evict() {
+	switch c.policy {
+	case PolicyLFU:
+		c.evictWorst(func(candidate, worst *Item) bool {
+			return atomic.LoadInt64(&candidate.Hits) < atomic.LoadInt64(&worst.Hits)
+		})
+	case PolicyRandom:
+		c.evictSample()
+	default: // PolicyLRU
+		c.evictWorst(func(candidate, worst *Item) bool {
+			return atomic.LoadInt64(&candidate.Accessed) < atomic.LoadInt64(&worst.Accessed)
+		})
+	}
+}
+
+// evictWorst scans every non-persistent item and deletes the one for which
+// worse(candidate, currentWorst) holds most often, i.e. the smallest by
+// that ordering.
+func (c *Cache) evictWorst(worse func(candidate, worst *Item) bool) {
+	var victimKey string
+	var victim *Item
+	c.store.Range(func(k string, item *Item) bool {
+		if item.Persistent {
+			return true
+		}
+		if victim == nil || worse(item, victim) {
+			victimKey, victim = k, item
+		}
+		return true
+	})
+	if victim != nil {
+		c.Delete(victimKey)
+	}
+}
+
+// evictSample evicts the oldest of the first randomSampleSize non-persistent
+// items it encounters, rather than scanning the whole cache for an exact
+// least-recently-used victim.
+func (c *Cache) evictSample() {
+	var victimKey string
+	var victim *Item
+	sampled := 0
+	c.store.Range(func(k string, item *Item) bool {
+		if item.Persistent {
+			return true
+		}
+		if victim == nil || atomic.LoadInt64(&item.Accessed) < atomic.LoadInt64(&victim.Accessed) {
+			victimKey, victim = k, item
+		}
+		sampled++
+		return sampled < randomSampleSize
+	})
+	if victim != nil {
+		c.Delete(victimKey)
+	}
+}
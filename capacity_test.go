@@ -0,0 +1,81 @@
+package ttlcache
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func countItems(c *Cache) int {
+	n := 0
+	c.Range(func(_, _ interface{}) bool {
+		n++
+		return true
+	})
+	return n
+}
+
+// TestCapacityAccountingLazyExpiry guards against a regression where Load's
+// lazy-expiry path deleted straight from the store instead of going through
+// Delete, leaking the capacity counter. With cleanupInterval 0 there's no
+// janitor, so Load is the only thing that ever reaps these items.
+func TestCapacityAccountingLazyExpiry(t *testing.T) {
+	c := NewWithCapacity(NoExpiration, 0, 3, PolicyLRU)
+
+	for i := 0; i < 3; i++ {
+		c.Store(fmt.Sprintf("k%d", i), i, time.Millisecond, false)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	for i := 0; i < 3; i++ {
+		if _, found := c.Load(fmt.Sprintf("k%d", i)); found {
+			t.Fatalf("k%d unexpectedly still present", i)
+		}
+	}
+
+	// If the counter had leaked, these Stores would think the cache was
+	// still full and evict each other instead of all surviving.
+	for i := 0; i < 3; i++ {
+		c.Store(fmt.Sprintf("n%d", i), i, time.Hour, false)
+	}
+	if n := countItems(c); n != 3 {
+		t.Fatalf("expected 3 items after refill, got %d (capacity counter leaked)", n)
+	}
+}
+
+// TestCapacityAccountingAdd guards against Add bypassing the capacity
+// counter it relies on Store to maintain.
+func TestCapacityAccountingAdd(t *testing.T) {
+	c := NewWithCapacity(NoExpiration, 0, 2, PolicyLRU)
+
+	for _, k := range []string{"a", "b", "c"} {
+		if err := c.Add(k, k, time.Hour, false); err != nil {
+			t.Fatalf("Add(%q): %v", k, err)
+		}
+	}
+	if n := countItems(c); n != 2 {
+		t.Fatalf("expected capacity to cap Add-inserted items at 2, got %d", n)
+	}
+}
+
+// TestCapacityAccountingRestore guards against Restore bypassing the
+// capacity counter it relies on Store to maintain.
+func TestCapacityAccountingRestore(t *testing.T) {
+	src := New(NoExpiration, 0)
+	for i := 0; i < 5; i++ {
+		src.Store(fmt.Sprintf("r%d", i), i, time.Hour, false)
+	}
+	var buf bytes.Buffer
+	if err := src.Save(&buf); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	dst := NewWithCapacity(NoExpiration, 0, 2, PolicyLRU)
+	if err := dst.Restore(&buf); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if n := countItems(dst); n != 2 {
+		t.Fatalf("expected capacity to cap Restore-inserted items at 2, got %d", n)
+	}
+}
@@ -0,0 +1,240 @@
+// Command ttlcache generates a strongly-typed variant of ttlcache.Cache for
+// a single value type, so that callers of Store/Load don't pay for
+// interface{} boxing and type assertions. It is meant to be invoked with
+// go generate, e.g.:
+//
+//	//go:generate ttlcache -o foo_cache.go -pkg mypkg -t *Widget
+//
+// The generated file declares its own Item/Cache/New and mirrors ttlcache's
+// Store, Load, Delete, Range and DeleteExpired, except that Store, Load and
+// Range use the concrete type given by -t instead of interface{} (Range
+// passes the stored value, not the *Item). It has no Add, Replace,
+// OnEvicted, persistence, capacity bound or sharded backend.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"strings"
+	"text/template"
+)
+
+var (
+	typeName = flag.String("t", "", "type name to generate a Cache for, e.g. *Widget (required)")
+	output   = flag.String("o", "", "output file name (required)")
+	pkgName  = flag.String("pkg", "", "package name of the generated file (defaults to the package in the current directory)")
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("ttlcache: ")
+	flag.Parse()
+
+	if *typeName == "" || *output == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	pkg := *pkgName
+	if pkg == "" {
+		name, err := packageNameInDir(".")
+		if err != nil {
+			log.Fatalf("determining package name: %v", err)
+		}
+		pkg = name
+	}
+
+	src, err := render(pkg, *typeName)
+	if err != nil {
+		log.Fatalf("generating code: %v", err)
+	}
+
+	if err := os.WriteFile(*output, src, 0644); err != nil {
+		log.Fatalf("writing %s: %v", *output, err)
+	}
+}
+
+// packageNameInDir parses the Go package in dir (without type-checking) and
+// returns its package name, so the generated file can match it when -pkg
+// isn't given explicitly.
+func packageNameInDir(dir string) (string, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, nil, parser.PackageClauseOnly)
+	if err != nil {
+		return "", err
+	}
+	for name := range pkgs {
+		if strings.HasSuffix(name, "_test") {
+			continue
+		}
+		return name, nil
+	}
+	return "", fmt.Errorf("no Go package found in %s", dir)
+}
+
+func render(pkg, typeName string) ([]byte, error) {
+	data := struct {
+		Package string
+		Type    string
+	}{
+		Package: pkg,
+		Type:    typeName,
+	}
+
+	var buf bytes.Buffer
+	if err := cacheTemplate.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		// Return the unformatted source too, so the caller can inspect the
+		// generated code that failed to gofmt.
+		return buf.Bytes(), err
+	}
+	return src, nil
+}
+
+var cacheTemplate = template.Must(template.New("cache").Parse(`// Code generated by ttlcache -t {{.Type}}; DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"runtime"
+	"sync"
+	"time"
+)
+
+// Item is a {{.Type}} cache value with a ttl.
+type Item struct {
+	Persistent bool
+	Object     {{.Type}}
+	Expiration time.Time
+}
+
+// Expired returns true if the item has expired.
+func (item Item) Expired() bool {
+	if item.Persistent {
+		return false
+	}
+	return time.Now().After(item.Expiration)
+}
+
+const (
+	// NoExpiration is for use with functions that take an expiration time.
+	NoExpiration time.Duration = -1
+	// DefaultExpiration is for use with functions that take an expiration
+	// time. Equivalent to passing in the same expiration duration as was
+	// given to New() when the cache was created.
+	DefaultExpiration time.Duration = 0
+)
+
+// Cache is a {{.Type}}-typed kv map with ttl.
+type Cache struct {
+	defaultExpiration time.Duration
+	*sync.Map
+	stop chan bool
+}
+
+// Store adds an item to the cache, replacing any existing item. If the
+// duration is 0 (DefaultExpiration), the cache's default expiration time is
+// used. If it is -1 (NoExpiration), the item never expires.
+func (c *Cache) Store(k string, x {{.Type}}, d time.Duration, persistent bool) {
+	var e time.Time
+	if d == DefaultExpiration {
+		d = c.defaultExpiration
+	}
+	if d > 0 {
+		e = time.Now().Add(d)
+	}
+	c.Map.Store(k, &Item{
+		Persistent: persistent,
+		Object:     x,
+		Expiration: e,
+	})
+}
+
+// Load an item from the cache. Returns the zero value of {{.Type}} and false
+// if the key wasn't found or has expired.
+func (c *Cache) Load(k string) ({{.Type}}, bool) {
+	v, found := c.Map.Load(k)
+	if !found {
+		var zero {{.Type}}
+		return zero, false
+	}
+	item := v.(*Item)
+	if !item.Persistent {
+		if time.Now().After(item.Expiration) {
+			c.Map.Delete(k)
+			var zero {{.Type}}
+			return zero, false
+		}
+	}
+	return item.Object, true
+}
+
+// Delete an item from the cache. Does nothing if the key is not in the cache.
+func (c *Cache) Delete(k string) {
+	c.Map.Delete(k)
+}
+
+// Range calls f sequentially for each key and value present in the map. If f returns false, range stops the iteration.
+func (c *Cache) Range(f func(key string, value {{.Type}}) bool) {
+	c.Map.Range(func(k, v interface{}) bool {
+		return f(k.(string), v.(*Item).Object)
+	})
+}
+
+// DeleteExpired iterates the map and deletes expired items.
+func (c *Cache) DeleteExpired() {
+	c.Map.Range(func(k, v interface{}) bool {
+		item := v.(*Item)
+		if item.Expired() {
+			c.Delete(k.(string))
+		}
+		return true
+	})
+}
+
+func stopGC(c *Cache) {
+	c.stop <- true
+}
+
+func (c *Cache) gc(ci time.Duration) {
+	ticker := time.NewTicker(ci)
+	for {
+		select {
+		case <-ticker.C:
+			c.DeleteExpired()
+		case <-c.stop:
+			ticker.Stop()
+			return
+		}
+	}
+}
+
+// New returns a new {{.Type}} cache with a given default expiration duration
+// and cleanup interval. If the expiration duration is less than one (or
+// NoExpiration), the items in the cache never expire (by default), and must
+// be deleted manually. If the cleanup interval is less than one, expired
+// items are not deleted from the cache before calling c.DeleteExpired().
+func New(defaultExpiration, cleanupInterval time.Duration) *Cache {
+	if defaultExpiration == 0 {
+		defaultExpiration = -1
+	}
+	c := &Cache{
+		defaultExpiration: defaultExpiration,
+		Map:               &sync.Map{},
+	}
+	if defaultExpiration > 0 {
+		go c.gc(cleanupInterval)
+		runtime.SetFinalizer(c, stopGC)
+	}
+	return c
+}
+`))
@@ -0,0 +1,10 @@
+package ttlcache
+
+// OnEvicted sets a function to call when an item is evicted from the
+// cache: explicitly via Delete, by the janitor's DeleteExpired sweep, when
+// Load finds it expired, or to keep a NewWithCapacity cache within its
+// limit. Set to nil to disable (the default). The callback is invoked
+// with the key and the evicted item's value.
+func (c *Cache) OnEvicted(f func(key string, value interface{})) {
+	c.onEvicted = f
+}
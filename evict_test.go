@@ -0,0 +1,108 @@
+package ttlcache
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestStoreLoadAndDeleteIsAtomic is a deterministic regression test for the
+// property that made Cache.Delete safe to fix with LoadAndDelete: of N
+// goroutines racing to LoadAndDelete the same key, exactly one may observe
+// loaded == true. A naive Load-then-Delete pair can't guarantee this (two
+// goroutines can both Load before either Deletes), which let Cache.Delete
+// fire onEvicted (and decrement the capacity counter) twice for a single
+// logical eviction.
+func TestStoreLoadAndDeleteIsAtomic(t *testing.T) {
+	for name, newStore := range map[string]func() store{
+		"syncMapStore": func() store { return &syncMapStore{} },
+		"shardedStore": func() store { return newShardedStore(4) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			const goroutines = 16
+			for i := 0; i < 50; i++ {
+				s := newStore()
+				s.Store("k", &Item{Persistent: true})
+
+				var loadedCount int64
+				var start, done sync.WaitGroup
+				start.Add(1)
+				for g := 0; g < goroutines; g++ {
+					done.Add(1)
+					go func() {
+						defer done.Done()
+						start.Wait()
+						if _, loaded := s.LoadAndDelete("k"); loaded {
+							atomic.AddInt64(&loadedCount, 1)
+						}
+					}()
+				}
+				start.Done()
+				done.Wait()
+
+				if loadedCount != 1 {
+					t.Fatalf("%d of %d concurrent LoadAndDelete calls observed the item; want exactly 1", loadedCount, goroutines)
+				}
+			}
+		})
+	}
+}
+
+// TestDeleteConcurrentOnEvictedFiresOnce stress-tests Cache.Delete itself:
+// many goroutines deleting the same key concurrently must fire onEvicted
+// exactly once, since Delete is built on the atomic primitive above.
+func TestDeleteConcurrentOnEvictedFiresOnce(t *testing.T) {
+	c := New(NoExpiration, 0)
+	c.Store("k", 1, NoExpiration, true)
+
+	var evictions int64
+	c.OnEvicted(func(key string, value interface{}) {
+		atomic.AddInt64(&evictions, 1)
+	})
+
+	const goroutines = 16
+	var start, done sync.WaitGroup
+	start.Add(1)
+	for i := 0; i < goroutines; i++ {
+		done.Add(1)
+		go func() {
+			defer done.Done()
+			start.Wait()
+			c.Delete("k")
+		}()
+	}
+	start.Done()
+	done.Wait()
+
+	if evictions != 1 {
+		t.Fatalf("onEvicted fired %d times for one key deleted concurrently by %d goroutines; want 1", evictions, goroutines)
+	}
+}
+
+// TestDeleteConcurrentCapacityAccounting is like
+// TestDeleteConcurrentOnEvictedFiresOnce, but for the capacity counter:
+// double-removing the same key must decrement count exactly once, or the
+// counter drifts low and the cache silently grows past maxItems.
+func TestDeleteConcurrentCapacityAccounting(t *testing.T) {
+	c := NewWithCapacity(NoExpiration, 0, 100, PolicyLRU)
+	c.Store("k", 1, time.Hour, false)
+
+	const goroutines = 16
+	var start, done sync.WaitGroup
+	start.Add(1)
+	for i := 0; i < goroutines; i++ {
+		done.Add(1)
+		go func() {
+			defer done.Done()
+			start.Wait()
+			c.Delete("k")
+		}()
+	}
+	start.Done()
+	done.Wait()
+
+	if got := atomic.LoadInt64(&c.count); got != 0 {
+		t.Fatalf("capacity counter = %d after deleting the only item concurrently from %d goroutines; want 0", got, goroutines)
+	}
+}
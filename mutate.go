@@ -0,0 +1,51 @@
+package ttlcache
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// Add an item to the cache, only if an unexpired item doesn't already exist
+// for k. Returns an error otherwise. It is atomic with respect to concurrent
+// Store/Load/Delete calls on the same key.
+func (c *Cache) Add(k string, x interface{}, d time.Duration, persistent bool) error {
+	item := c.newItem(x, d, persistent)
+	for {
+		actual, loaded := c.store.LoadOrStore(k, item)
+		if !loaded {
+			if c.maxItems > 0 && atomic.AddInt64(&c.count, 1) > int64(c.maxItems) {
+				c.evict()
+			}
+			return nil
+		}
+		if !actual.Expired() {
+			return fmt.Errorf("item %s already exists", k)
+		}
+		// The existing item has expired but hasn't been swept yet; try to
+		// take its place atomically rather than erroring out. k isn't a
+		// new key here, so the capacity count is unaffected.
+		if c.store.CompareAndSwap(k, actual, item) {
+			return nil
+		}
+	}
+}
+
+// Replace sets a new value for the key k only if an unexpired item already
+// exists for it. Returns an error otherwise. It is atomic with respect to
+// concurrent Store/Load/Delete calls on the same key.
+func (c *Cache) Replace(k string, x interface{}, d time.Duration, persistent bool) error {
+	for {
+		actual, found := c.store.Load(k)
+		if !found {
+			return fmt.Errorf("item %s doesn't exist", k)
+		}
+		if actual.Expired() {
+			return fmt.Errorf("item %s doesn't exist", k)
+		}
+		item := c.newItem(x, d, persistent)
+		if c.store.CompareAndSwap(k, actual, item) {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package ttlcache
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestAddConcurrentOnlyOneWins hammers Add on a single new key from many
+// goroutines at once; exactly one may succeed, since Add is documented as
+// atomic with respect to concurrent Store/Load/Delete on the same key.
+func TestAddConcurrentOnlyOneWins(t *testing.T) {
+	c := New(NoExpiration, 0)
+
+	const goroutines = 32
+	var successes int64
+	var start, done sync.WaitGroup
+	start.Add(1)
+	for i := 0; i < goroutines; i++ {
+		done.Add(1)
+		go func(i int) {
+			defer done.Done()
+			start.Wait()
+			if err := c.Add("k", i, time.Hour, false); err == nil {
+				atomic.AddInt64(&successes, 1)
+			}
+		}(i)
+	}
+	start.Done()
+	done.Wait()
+
+	if successes != 1 {
+		t.Fatalf("%d of %d concurrent Add calls succeeded on the same new key; want exactly 1", successes, goroutines)
+	}
+	if _, found := c.Load("k"); !found {
+		t.Fatal("expected k to be present after a winning Add")
+	}
+}
+
+// TestReplaceConcurrentRequiresExisting hammers Replace on a key that other
+// goroutines keep re-Storing and Deleting; Replace must never panic or race
+// with Store/Delete under -race. It then checks that Replace on the deleted
+// key fails rather than creating it.
+func TestReplaceConcurrentRequiresExisting(t *testing.T) {
+	c := New(NoExpiration, 0)
+	c.Store("k", 0, time.Hour, false)
+
+	const goroutines = 32
+	var start, done sync.WaitGroup
+	start.Add(1)
+	for i := 0; i < goroutines; i++ {
+		done.Add(1)
+		go func(i int) {
+			defer done.Done()
+			start.Wait()
+			switch i % 3 {
+			case 0:
+				c.Replace("k", i, time.Hour, false)
+			case 1:
+				c.Store("k", i, time.Hour, false)
+			default:
+				c.Delete("k")
+			}
+		}(i)
+	}
+	start.Done()
+	done.Wait()
+
+	// No assertion on the value the race leaves behind (the interleaving is
+	// nondeterministic by design); the race's job is to catch data races and
+	// panics under `go test -race`.
+
+	// Once k is gone, Replace must fail and must not create it.
+	c.Delete("k")
+	if err := c.Replace("k", -1, time.Hour, false); err == nil {
+		t.Fatal("Replace succeeded on a key that doesn't exist")
+	}
+	if _, found := c.Load("k"); found {
+		t.Fatal("Replace created a key that didn't exist")
+	}
+}
@@ -0,0 +1,70 @@
+package ttlcache
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestSaveRestoreRoundTrip(t *testing.T) {
+	src := New(NoExpiration, 0)
+	src.Store("a", "apple", time.Hour, false)
+	src.Store("b", 42, NoExpiration, true)
+
+	var buf bytes.Buffer
+	if err := src.Save(&buf); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	dst := New(NoExpiration, 0)
+	if err := dst.Restore(&buf); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+
+	v, found := dst.Load("a")
+	if !found || v != "apple" {
+		t.Fatalf("Load(a) = %v, %v; want apple, true", v, found)
+	}
+	v, found = dst.Load("b")
+	if !found || v != 42 {
+		t.Fatalf("Load(b) = %v, %v; want 42, true", v, found)
+	}
+}
+
+func TestRestorePreservesExistingUnexpiredItems(t *testing.T) {
+	src := New(NoExpiration, 0)
+	src.Store("a", "from-snapshot", time.Hour, false)
+	var buf bytes.Buffer
+	if err := src.Save(&buf); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	dst := New(NoExpiration, 0)
+	dst.Store("a", "already-here", time.Hour, false)
+	if err := dst.Restore(&buf); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+
+	v, found := dst.Load("a")
+	if !found || v != "already-here" {
+		t.Fatalf("Restore overwrote an unexpired existing item: Load(a) = %v, %v; want already-here, true", v, found)
+	}
+}
+
+func TestSaveFileRestoreFileRoundTrip(t *testing.T) {
+	fname := t.TempDir() + "/cache.gob"
+
+	src := New(NoExpiration, 0)
+	src.Store("a", "apple", time.Hour, false)
+	if err := src.SaveFile(fname); err != nil {
+		t.Fatalf("SaveFile: %v", err)
+	}
+
+	dst := New(NoExpiration, 0)
+	if err := dst.RestoreFile(fname); err != nil {
+		t.Fatalf("RestoreFile: %v", err)
+	}
+	if v, found := dst.Load("a"); !found || v != "apple" {
+		t.Fatalf("Load(a) = %v, %v; want apple, true", v, found)
+	}
+}
@@ -0,0 +1,153 @@
+package ttlcache
+
+import (
+	"sync"
+	"time"
+)
+
+// defaultShards is the shard count used by NewSharded when shards is <= 0.
+const defaultShards = 256
+
+// shardedStore is a store split into a fixed number of power-of-two shards,
+// each holding its own map[string]*Item guarded by a sync.RWMutex. It
+// trades sync.Map's read-mostly optimizations for better throughput on
+// write-heavy, high-concurrency workloads, since DeleteExpired and other
+// callers only ever contend a single shard at a time instead of the whole
+// cache.
+type shardedStore struct {
+	shards []*cacheShard
+	mask   uint32
+}
+
+type cacheShard struct {
+	mu sync.RWMutex
+	m  map[string]*Item
+}
+
+func newShardedStore(shards int) *shardedStore {
+	if shards <= 0 {
+		shards = defaultShards
+	}
+	shards = nextPowerOfTwo(shards)
+
+	s := &shardedStore{
+		shards: make([]*cacheShard, shards),
+		mask:   uint32(shards - 1),
+	}
+	for i := range s.shards {
+		s.shards[i] = &cacheShard{m: make(map[string]*Item)}
+	}
+	return s
+}
+
+func (s *shardedStore) shardFor(key string) *cacheShard {
+	return s.shards[fnv32a(key)&s.mask]
+}
+
+func (s *shardedStore) Load(key string) (*Item, bool) {
+	sh := s.shardFor(key)
+	sh.mu.RLock()
+	item, ok := sh.m[key]
+	sh.mu.RUnlock()
+	return item, ok
+}
+
+func (s *shardedStore) Store(key string, item *Item) {
+	sh := s.shardFor(key)
+	sh.mu.Lock()
+	sh.m[key] = item
+	sh.mu.Unlock()
+}
+
+func (s *shardedStore) Delete(key string) {
+	sh := s.shardFor(key)
+	sh.mu.Lock()
+	delete(sh.m, key)
+	sh.mu.Unlock()
+}
+
+func (s *shardedStore) LoadAndDelete(key string) (*Item, bool) {
+	sh := s.shardFor(key)
+	sh.mu.Lock()
+	item, ok := sh.m[key]
+	delete(sh.m, key)
+	sh.mu.Unlock()
+	return item, ok
+}
+
+// Range visits one shard at a time, so DeleteExpired never has to block the
+// whole cache while it walks it.
+func (s *shardedStore) Range(f func(key string, item *Item) bool) {
+	for _, sh := range s.shards {
+		sh.mu.RLock()
+		snapshot := make(map[string]*Item, len(sh.m))
+		for k, v := range sh.m {
+			snapshot[k] = v
+		}
+		sh.mu.RUnlock()
+		for k, v := range snapshot {
+			if !f(k, v) {
+				return
+			}
+		}
+	}
+}
+
+func (s *shardedStore) LoadOrStore(key string, item *Item) (*Item, bool) {
+	sh := s.shardFor(key)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	if actual, ok := sh.m[key]; ok {
+		return actual, true
+	}
+	sh.m[key] = item
+	return item, false
+}
+
+func (s *shardedStore) CompareAndSwap(key string, old, new *Item) bool {
+	sh := s.shardFor(key)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	if sh.m[key] != old {
+		return false
+	}
+	sh.m[key] = new
+	return true
+}
+
+// fnv32a hashes key with 32-bit FNV-1a, used to pick a shard.
+func fnv32a(key string) uint32 {
+	const (
+		offset32 = 2166136261
+		prime32  = 16777619
+	)
+	h := uint32(offset32)
+	for i := 0; i < len(key); i++ {
+		h ^= uint32(key[i])
+		h *= prime32
+	}
+	return h
+}
+
+func nextPowerOfTwo(n int) int {
+	p := 1
+	for p < n {
+		p <<= 1
+	}
+	return p
+}
+
+// NewSharded returns a new cache like New, but backed by an N-way sharded
+// map instead of a single sync.Map. sync.Map is optimized for read-mostly,
+// disjoint-key access; a sharded cache typically performs better under
+// write-heavy TTL churn because Store/Delete only ever contend the shard
+// the key hashes to. shards is rounded up to the next power of two; if it
+// is <= 0, defaultShards (256) is used.
+//
+// The rest of the Cache API is unchanged, so callers switch to a sharded
+// cache by changing only the constructor.
+func NewSharded(defaultExpiration, cleanupInterval time.Duration, shards int) *Cache {
+	c := newCache(defaultExpiration, newShardedStore(shards))
+	startGC(c, cleanupInterval)
+	return c
+}
@@ -0,0 +1,62 @@
+package ttlcache
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNewShardedBasicOps(t *testing.T) {
+	c := NewSharded(NoExpiration, 0, 16)
+
+	c.Store("a", "apple", time.Hour, false)
+	if v, found := c.Load("a"); !found || v != "apple" {
+		t.Fatalf("Load(a) = %v, %v; want apple, true", v, found)
+	}
+
+	c.Delete("a")
+	if _, found := c.Load("a"); found {
+		t.Fatal("a still present after Delete")
+	}
+}
+
+// TestNewShardedConcurrentStoreLoadDelete hammers Store/Load/Delete across
+// many keys and goroutines to exercise the sharded backend's per-shard
+// locking under `go test -race`.
+func TestNewShardedConcurrentStoreLoadDelete(t *testing.T) {
+	c := NewSharded(NoExpiration, 0, 8)
+
+	const goroutines = 16
+	const perGoroutine = 200
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < perGoroutine; i++ {
+				k := fmt.Sprintf("k-%d-%d", g, i%8)
+				c.Store(k, i, time.Hour, false)
+				c.Load(k)
+				if i%4 == 0 {
+					c.Delete(k)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+}
+
+// TestNewShardedDefaultsToPowerOfTwoShards guards the documented behavior
+// that a non-power-of-two (or non-positive) shard count is rounded up.
+func TestNewShardedDefaultsToPowerOfTwoShards(t *testing.T) {
+	s := newShardedStore(5)
+	if n := len(s.shards); n != 8 {
+		t.Fatalf("newShardedStore(5) used %d shards; want 8", n)
+	}
+
+	s = newShardedStore(0)
+	if n := len(s.shards); n != defaultShards {
+		t.Fatalf("newShardedStore(0) used %d shards; want default %d", n, defaultShards)
+	}
+}
@@ -0,0 +1,66 @@
+package ttlcache
+
+import "sync"
+
+// store is the storage backend used by Cache. Implementations must be safe
+// for concurrent use by multiple goroutines. New() uses a sync.Map-backed
+// store; NewSharded() uses a sharded, mutex-guarded one.
+type store interface {
+	Load(key string) (*Item, bool)
+	Store(key string, item *Item)
+	Delete(key string)
+	Range(f func(key string, item *Item) bool)
+	LoadOrStore(key string, item *Item) (actual *Item, loaded bool)
+	CompareAndSwap(key string, old, new *Item) bool
+	// LoadAndDelete atomically removes key and returns the item that was
+	// there, if any. Callers that need to act on (or count) an item
+	// exactly once as it's removed must use this instead of a separate
+	// Load followed by Delete, which races with a concurrent delete of
+	// the same key.
+	LoadAndDelete(key string) (item *Item, loaded bool)
+}
+
+// syncMapStore is a store backed by a single sync.Map, which is optimized
+// for read-mostly, disjoint-key workloads.
+type syncMapStore struct {
+	m sync.Map
+}
+
+func (s *syncMapStore) Load(key string) (*Item, bool) {
+	v, ok := s.m.Load(key)
+	if !ok {
+		return nil, false
+	}
+	return v.(*Item), true
+}
+
+func (s *syncMapStore) Store(key string, item *Item) {
+	s.m.Store(key, item)
+}
+
+func (s *syncMapStore) Delete(key string) {
+	s.m.Delete(key)
+}
+
+func (s *syncMapStore) Range(f func(key string, item *Item) bool) {
+	s.m.Range(func(k, v interface{}) bool {
+		return f(k.(string), v.(*Item))
+	})
+}
+
+func (s *syncMapStore) LoadOrStore(key string, item *Item) (*Item, bool) {
+	actual, loaded := s.m.LoadOrStore(key, item)
+	return actual.(*Item), loaded
+}
+
+func (s *syncMapStore) CompareAndSwap(key string, old, new *Item) bool {
+	return s.m.CompareAndSwap(key, old, new)
+}
+
+func (s *syncMapStore) LoadAndDelete(key string) (*Item, bool) {
+	v, loaded := s.m.LoadAndDelete(key)
+	if !loaded {
+		return nil, false
+	}
+	return v.(*Item), true
+}